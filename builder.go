@@ -0,0 +1,95 @@
+package dist
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+)
+
+// threads is the worker-pool size used by NewBuilder and FillPairs.
+var threads = runtime.NumCPU()
+
+// SetThreads overrides the worker-pool size used by NewBuilder and
+// FillPairs. It has no effect when n is not positive.
+func SetThreads(n int) {
+	if n > 0 {
+		threads = n
+	}
+}
+
+// A PairFunc computes the distance between taxa i and j. Returning an
+// error, or a NaN distance, aborts the build.
+type PairFunc func(i, j int) (float64, error)
+
+// NewBuilder builds a DistMat for names, computing every pairwise distance
+// in parallel by calling fn once for each i < j and mirroring the result
+// into both triangles. Useful for plugging in sequence-, alignment-, or
+// k-mer-distance functions without writing the scheduling by hand.
+func NewBuilder(names []string, fn PairFunc) (*DistMat, error) {
+	n := len(names)
+	d := NewDistMat(n)
+	copy(d.Names, names)
+	pairs := make([][2]int, 0, n*(n-1)/2)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			pairs = append(pairs, [2]int{i, j})
+		}
+	}
+	if err := d.FillPairs(pairs, fn); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// The method FillPairs computes the distances for the given (i, j) pairs
+// in parallel, using a worker pool sized by SetThreads (runtime.NumCPU()
+// by default), and mirrors each result into both triangles of the matrix.
+// It is meant for incremental updates, such as distances involving taxa
+// just added with Append. FillPairs returns the first error fn reports, or
+// an error if fn returns NaN, aborting the remaining pairs early; entries
+// already computed are left in place.
+func (d *DistMat) FillPairs(pairs [][2]int, fn PairFunc) error {
+	n := threads
+	if n < 1 {
+		n = 1
+	}
+	jobs := make(chan [2]int)
+	abort := make(chan struct{})
+	var once sync.Once
+	var firstErr error
+	var wg sync.WaitGroup
+	for w := 0; w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range jobs {
+				v, err := fn(p[0], p[1])
+				if err == nil && math.IsNaN(v) {
+					err = fmt.Errorf("dist: NaN distance for pair (%d, %d)", p[0], p[1])
+				}
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						close(abort)
+					})
+					continue
+				}
+				d.Matrix[p[0]][p[1]] = v
+				d.Matrix[p[1]][p[0]] = v
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for _, p := range pairs {
+			select {
+			case <-abort:
+				return
+			case jobs <- p:
+			}
+		}
+	}()
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,100 @@
+package dist
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestNewBuilderMirrors(t *testing.T) {
+	names := []string{"a", "b", "c", "d", "e"}
+	d, err := NewBuilder(names, func(i, j int) (float64, error) {
+		return float64(10*i + j), nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	n := len(names)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			want := float64(10*i + j)
+			if d.Matrix[i][j] != want {
+				t.Errorf("Matrix[%d][%d] = %v, want %v", i, j, d.Matrix[i][j], want)
+			}
+			if d.Matrix[j][i] != d.Matrix[i][j] {
+				t.Errorf("Matrix[%d][%d] = %v != Matrix[%d][%d] = %v, not mirrored",
+					j, i, d.Matrix[j][i], i, j, d.Matrix[i][j])
+			}
+		}
+	}
+}
+
+func TestNewBuilderSetThreads(t *testing.T) {
+	orig := threads
+	defer SetThreads(orig)
+	SetThreads(1)
+	names := []string{"a", "b", "c", "d"}
+	d, err := NewBuilder(names, func(i, j int) (float64, error) {
+		return float64(10*i + j), nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Matrix[0][2] != 2 || d.Matrix[2][0] != 2 {
+		t.Errorf("SetThreads(1) build got wrong distance: %v", d.Matrix[0][2])
+	}
+	SetThreads(0) // must be a no-op, not zero out the pool
+	if threads != 1 {
+		t.Errorf("SetThreads(0) changed threads to %d, want it left at 1", threads)
+	}
+}
+
+func TestNewBuilderError(t *testing.T) {
+	names := []string{"a", "b", "c", "d"}
+	wantErr := errors.New("boom")
+	_, err := NewBuilder(names, func(i, j int) (float64, error) {
+		if i == 1 && j == 3 {
+			return 0, wantErr
+		}
+		return float64(i + j), nil
+	})
+	if !errors.Is(err, wantErr) {
+		t.Errorf("get error %v, want %v", err, wantErr)
+	}
+}
+
+func TestNewBuilderNaN(t *testing.T) {
+	names := []string{"a", "b", "c"}
+	_, err := NewBuilder(names, func(i, j int) (float64, error) {
+		if i == 0 && j == 2 {
+			return math.NaN(), nil
+		}
+		return float64(i + j), nil
+	})
+	if err == nil {
+		t.Errorf("expected an error for a NaN distance, got nil")
+	}
+}
+
+func TestFillPairsPartial(t *testing.T) {
+	d := NewDistMat(4)
+	for i := range d.Names {
+		d.Names[i] = fmt.Sprintf("t%d", i)
+	}
+	err := d.FillPairs([][2]int{{0, 3}, {1, 2}}, func(i, j int) (float64, error) {
+		return float64(i + j), nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.Matrix[0][3] != 3 || d.Matrix[3][0] != 3 {
+		t.Errorf("pair (0,3) not mirrored: %v / %v", d.Matrix[0][3], d.Matrix[3][0])
+	}
+	if d.Matrix[1][2] != 3 || d.Matrix[2][1] != 3 {
+		t.Errorf("pair (1,2) not mirrored: %v / %v", d.Matrix[1][2], d.Matrix[2][1])
+	}
+	if d.Matrix[0][1] != 0 {
+		t.Errorf("pair (0,1) was not requested but got filled: %v", d.Matrix[0][1])
+	}
+}
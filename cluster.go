@@ -0,0 +1,141 @@
+package dist
+
+import "math"
+
+// A ClusterMethod selects the linkage rule used to merge clusters.
+type ClusterMethod int
+
+const (
+	// SingleLinkage merges clusters using the minimum inter-member distance.
+	SingleLinkage ClusterMethod = iota
+	// CompleteLinkage merges clusters using the maximum inter-member distance.
+	CompleteLinkage
+	// AverageLinkage merges clusters using the mean inter-member distance.
+	AverageLinkage
+)
+
+// A Merge records one step of agglomerative clustering, joining the two
+// nodes Left and Right at the given Height. Leaves are numbered 0..n-1 in
+// DistMat order; internal nodes are numbered n, n+1, ... in the order they
+// are created, so the i-th Merge returned by Linkage creates node n+i.
+type Merge struct {
+	Left, Right int
+	Height      float64
+}
+
+// The method Linkage performs agglomerative hierarchical clustering of the
+// distance matrix using the given ClusterMethod and returns the full
+// sequence of merges. The receiver is left unchanged.
+func (d *DistMat) Linkage(method ClusterMethod) []Merge {
+	n := len(d.Names)
+	if n < 2 {
+		return nil
+	}
+	active := make([]int, n)
+	for i := range active {
+		active[i] = i
+	}
+	members := make(map[int][]int, 2*n-1)
+	for i := 0; i < n; i++ {
+		members[i] = []int{i}
+	}
+	dist := make(map[[2]int]float64)
+	key := func(a, b int) [2]int {
+		if a > b {
+			a, b = b, a
+		}
+		return [2]int{a, b}
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			dist[key(i, j)] = d.Matrix[i][j]
+		}
+	}
+	merges := make([]Merge, 0, n-1)
+	nextID := n
+	for len(active) > 1 {
+		minD := math.MaxFloat64
+		bi, bj := active[0], active[1]
+		for x := 0; x < len(active); x++ {
+			for y := x + 1; y < len(active); y++ {
+				a, b := active[x], active[y]
+				if v := dist[key(a, b)]; v < minD {
+					minD, bi, bj = v, a, b
+				}
+			}
+		}
+		id := nextID
+		nextID++
+		merges = append(merges, Merge{Left: bi, Right: bj, Height: minD})
+		members[id] = append(append([]int{}, members[bi]...), members[bj]...)
+		for _, k := range active {
+			if k == bi || k == bj {
+				continue
+			}
+			var v float64
+			switch method {
+			case SingleLinkage:
+				v = math.Min(dist[key(bi, k)], dist[key(bj, k)])
+			case CompleteLinkage:
+				v = math.Max(dist[key(bi, k)], dist[key(bj, k)])
+			case AverageLinkage:
+				ni, nj := float64(len(members[bi])), float64(len(members[bj]))
+				v = (ni*dist[key(bi, k)] + nj*dist[key(bj, k)]) / (ni + nj)
+			}
+			dist[key(id, k)] = v
+		}
+		na := make([]int, 0, len(active)-1)
+		for _, a := range active {
+			if a != bi && a != bj {
+				na = append(na, a)
+			}
+		}
+		na = append(na, id)
+		active = na
+	}
+	return merges
+}
+
+// The method Cluster performs agglomerative hierarchical clustering of the
+// distance matrix using the given ClusterMethod and cuts the resulting
+// dendrogram at threshold, returning the taxon names grouped into clusters.
+// Taxa that never join another taxon at or below threshold are returned as
+// one-element groups.
+func (d *DistMat) Cluster(threshold float64, method ClusterMethod) [][]string {
+	n := len(d.Names)
+	if n == 0 {
+		return nil
+	}
+	if n == 1 {
+		return [][]string{{d.Names[0]}}
+	}
+	merges := d.Linkage(method)
+	members := make(map[int][]int, 2*n-1)
+	for i := 0; i < n; i++ {
+		members[i] = []int{i}
+	}
+	for i, mg := range merges {
+		id := n + i
+		members[id] = append(append([]int{}, members[mg.Left]...), members[mg.Right]...)
+	}
+	var collect func(id int) [][]string
+	collect = func(id int) [][]string {
+		if id < n {
+			return [][]string{{d.Names[id]}}
+		}
+		mg := merges[id-n]
+		if mg.Height <= threshold {
+			leaves := members[id]
+			names := make([]string, len(leaves))
+			for i, l := range leaves {
+				names[i] = d.Names[l]
+			}
+			return [][]string{names}
+		}
+		left := collect(mg.Left)
+		right := collect(mg.Right)
+		return append(left, right...)
+	}
+	root := n + len(merges) - 1
+	return collect(root)
+}
@@ -0,0 +1,82 @@
+package dist
+
+import (
+	"reflect"
+	"testing"
+)
+
+// linkageMat returns a 4-taxon matrix along a line (a=0, b=2, c=7, d=15),
+// chosen so single/complete/average linkage disagree on merge heights.
+func linkageMat() *DistMat {
+	names := []string{"a", "b", "c", "d"}
+	pos := []float64{0, 2, 7, 15}
+	d := NewDistMat(len(names))
+	copy(d.Names, names)
+	for i := range pos {
+		for j := range pos {
+			if i != j {
+				v := pos[i] - pos[j]
+				if v < 0 {
+					v = -v
+				}
+				d.Matrix[i][j] = v
+			}
+		}
+	}
+	return d
+}
+
+func TestLinkage(t *testing.T) {
+	tests := []struct {
+		method ClusterMethod
+		want   []Merge
+	}{
+		{SingleLinkage, []Merge{{0, 1, 2}, {2, 4, 5}, {3, 5, 8}}},
+		{CompleteLinkage, []Merge{{0, 1, 2}, {2, 4, 7}, {3, 5, 15}}},
+		{AverageLinkage, []Merge{{0, 1, 2}, {2, 4, 6}, {3, 5, 12}}},
+	}
+	for _, test := range tests {
+		d := linkageMat()
+		get := d.Linkage(test.method)
+		if !reflect.DeepEqual(get, test.want) {
+			t.Errorf("method %v: get %v, want %v", test.method, get, test.want)
+		}
+	}
+}
+
+func TestCluster(t *testing.T) {
+	tests := []struct {
+		threshold float64
+		method    ClusterMethod
+		want      [][]string
+	}{
+		{4, SingleLinkage, [][]string{{"d"}, {"c"}, {"a", "b"}}},
+		{10, CompleteLinkage, [][]string{{"d"}, {"c", "a", "b"}}},
+		{7, AverageLinkage, [][]string{{"d"}, {"c", "a", "b"}}},
+	}
+	for _, test := range tests {
+		d := linkageMat()
+		get := d.Cluster(test.threshold, test.method)
+		if !reflect.DeepEqual(get, test.want) {
+			t.Errorf("threshold %v method %v: get %v, want %v",
+				test.threshold, test.method, get, test.want)
+		}
+	}
+}
+
+func TestClusterEdgeCases(t *testing.T) {
+	d0 := NewDistMat(0)
+	if get := d0.Cluster(1, SingleLinkage); get != nil {
+		t.Errorf("n=0: get %v, want nil", get)
+	}
+	if get := d0.Linkage(SingleLinkage); get != nil {
+		t.Errorf("n=0 linkage: get %v, want nil", get)
+	}
+
+	d1 := NewDistMat(1)
+	d1.Names[0] = "x"
+	want := [][]string{{"x"}}
+	if get := d1.Cluster(1, SingleLinkage); !reflect.DeepEqual(get, want) {
+		t.Errorf("n=1: get %v, want %v", get, want)
+	}
+}
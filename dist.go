@@ -2,14 +2,10 @@
 package dist
 
 import (
-	"bufio"
 	"bytes"
 	"fmt"
-	"io"
 	"log"
 	"math"
-	"strconv"
-	"strings"
 	"text/tabwriter"
 )
 
@@ -19,12 +15,6 @@ type DistMat struct {
 	Names  []string
 }
 
-// A DistMat is read using a Scanner.
-type Scanner struct {
-	r   *bufio.Reader
-	mat *DistMat
-}
-
 // String returns a distance matrix as a table.
 func (d *DistMat) String() string {
 	mat := ""
@@ -177,47 +167,6 @@ func (d *DistMat) Max() (min float64, maxI, maxJ int) {
 	return max, maxI, maxJ
 }
 
-// Scan reads input matrix by matrix.
-func (s *Scanner) Scan() bool {
-	var err error
-	const num = "1234567890"
-	l, err := s.r.ReadString('\n')
-	for err == nil && strings.IndexAny(l, num) < 0 {
-		l, err = s.r.ReadString('\n')
-	}
-	if err != nil {
-		return false
-	}
-	l = strings.TrimRight(l, "\r\n")
-	n, err := strconv.Atoi(l)
-	if err != nil {
-		log.Fatalf("can't convert %q", l)
-	}
-	s.mat = NewDistMat(n)
-	for i := 0; i < n; i++ {
-		l, err = s.r.ReadString('\n')
-		if err != nil {
-			return false
-		}
-		fields := strings.Fields(l)
-		s.mat.Names[i] = fields[0]
-		for j := 1; j <= n; j++ {
-			s.mat.Matrix[i][j-1], err =
-				strconv.ParseFloat(fields[j], 64)
-			if err != nil {
-				log.Fatalf("can't read %q", fields[j])
-			}
-		}
-
-	}
-	return true
-}
-
-// The method DistanceMatrix returns the last DistMat scanned.
-func (s *Scanner) DistanceMatrix() *DistMat {
-	return s.mat
-}
-
 // Function NewSequence returns a new n x n DistMat.
 func NewDistMat(n int) *DistMat {
 	d := new(DistMat)
@@ -228,10 +177,3 @@ func NewDistMat(n int) *DistMat {
 	d.Names = make([]string, n)
 	return d
 }
-
-// The function NewScanner returns a new Scanner.
-func NewScanner(r io.Reader) *Scanner {
-	sc := new(Scanner)
-	sc.r = bufio.NewReader(r)
-	return sc
-}
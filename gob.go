@@ -0,0 +1,109 @@
+package dist
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"io"
+)
+
+func init() {
+	gob.Register(&DistMat{})
+}
+
+// gobDistMat mirrors DistMat and carries its exported fields through
+// encoding/gob without recursing into GobEncode/GobDecode.
+type gobDistMat struct {
+	Matrix [][]float64
+	Names  []string
+}
+
+// The method GobEncode implements gob.GobEncoder, letting a *DistMat be
+// shipped transparently over pipes or RPC between stages of a pipeline.
+func (d *DistMat) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(gobDistMat{Matrix: d.Matrix, Names: d.Names})
+	return buf.Bytes(), err
+}
+
+// The method GobDecode implements gob.GobDecoder.
+func (d *DistMat) GobDecode(data []byte) error {
+	var aux gobDistMat
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&aux); err != nil {
+		return err
+	}
+	d.Matrix = aux.Matrix
+	d.Names = aux.Names
+	return nil
+}
+
+// binMagic identifies the framed binary snapshot format written by
+// SaveBinary: the string "DMAT", a format version byte, the taxon count,
+// the taxon names, and finally the matrix, all little-endian.
+const binMagic = "DMAT\x01"
+
+// The method SaveBinary writes the matrix to w in a compact framed binary
+// format, for pipelines that want to cache a large matrix cheaply instead
+// of round-tripping it through PHYLIP text.
+func (d *DistMat) SaveBinary(w io.Writer) error {
+	n := len(d.Names)
+	if _, err := io.WriteString(w, binMagic); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(n)); err != nil {
+		return err
+	}
+	for _, name := range d.Names {
+		b := []byte(name)
+		if err := binary.Write(w, binary.LittleEndian, uint32(len(b))); err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if err := binary.Write(w, binary.LittleEndian, d.Matrix[i][j]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// LoadBinary reads a DistMat previously written by SaveBinary.
+func LoadBinary(r io.Reader) (*DistMat, error) {
+	magic := make([]byte, len(binMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, err
+	}
+	if string(magic) != binMagic {
+		return nil, fmt.Errorf("dist: not a binary snapshot (bad magic %q)", magic)
+	}
+	var n uint32
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return nil, err
+	}
+	d := NewDistMat(int(n))
+	for i := range d.Names {
+		var l uint32
+		if err := binary.Read(r, binary.LittleEndian, &l); err != nil {
+			return nil, err
+		}
+		b := make([]byte, l)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		d.Names[i] = string(b)
+	}
+	for i := range d.Matrix {
+		for j := range d.Matrix[i] {
+			if err := binary.Read(r, binary.LittleEndian, &d.Matrix[i][j]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return d, nil
+}
@@ -0,0 +1,54 @@
+package dist
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"testing"
+)
+
+func sampleDistMat() *DistMat {
+	d := NewDistMat(3)
+	copy(d.Names, []string{"a", "b", "c"})
+	d.Matrix[0] = []float64{0, 1, 2}
+	d.Matrix[1] = []float64{1, 0, 3}
+	d.Matrix[2] = []float64{2, 3, 0}
+	return d
+}
+
+func TestGobRoundTrip(t *testing.T) {
+	d := sampleDistMat()
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(d); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	var got DistMat
+	if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if !reflect.DeepEqual(d.Names, got.Names) || !reflect.DeepEqual(d.Matrix, got.Matrix) {
+		t.Errorf("get:\n%+v\nwant:\n%+v", got, d)
+	}
+}
+
+func TestSaveLoadBinary(t *testing.T) {
+	d := sampleDistMat()
+	var buf bytes.Buffer
+	if err := d.SaveBinary(&buf); err != nil {
+		t.Fatalf("SaveBinary: %v", err)
+	}
+	got, err := LoadBinary(&buf)
+	if err != nil {
+		t.Fatalf("LoadBinary: %v", err)
+	}
+	if !reflect.DeepEqual(d.Names, got.Names) || !reflect.DeepEqual(d.Matrix, got.Matrix) {
+		t.Errorf("get:\n%+v\nwant:\n%+v", got, d)
+	}
+}
+
+func TestLoadBinaryBadMagic(t *testing.T) {
+	buf := bytes.NewBufferString("NOT A SNAPSHOT AT ALL")
+	if _, err := LoadBinary(buf); err == nil {
+		t.Errorf("expected an error for a bad magic, got nil")
+	}
+}
@@ -0,0 +1,545 @@
+package dist
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"iter"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A Format identifies the on-disk representation of a distance matrix.
+type Format int
+
+const (
+	// Auto detects the format from the input's first non-empty line.
+	Auto Format = iota
+	// PhylipSquare is the classic square PHYLIP distance matrix format,
+	// a taxon count followed by one fully populated row per taxon.
+	PhylipSquare
+	// PhylipLower is the lower-triangular PHYLIP distance matrix format,
+	// where row i holds only the i entries below the diagonal.
+	PhylipLower
+	// CSV is a comma-separated table, a header row of taxon names
+	// followed by one row per taxon, each prefixed with its name.
+	CSV
+	// NumPy is a little-endian float64 .npy array holding the square
+	// matrix; taxon names are read from and written to a sidecar.
+	NumPy
+)
+
+// npyMagic is the 6-byte magic string at the start of every .npy file.
+const npyMagic = "\x93NUMPY"
+
+// A DistMat is read using a Scanner.
+type Scanner struct {
+	raw         io.Reader
+	r           *bufio.Reader
+	mat         *DistMat
+	format      Format
+	strictNames bool
+	namesReader io.Reader
+	err         error
+	bufSize     int
+}
+
+// The function NewScanner returns a new Scanner that reads the square
+// PHYLIP format, as written by String.
+func NewScanner(r io.Reader) *Scanner {
+	return NewScannerFormat(r, PhylipSquare)
+}
+
+// The function NewScannerFormat returns a new Scanner for the given Format.
+// Pass Auto to detect the format from the first non-empty line of r.
+func NewScannerFormat(r io.Reader, f Format) *Scanner {
+	sc := new(Scanner)
+	sc.raw = r
+	sc.format = f
+	return sc
+}
+
+// The method StrictNames controls whether PhylipLower parsing requires
+// each row's values to be on the same line as its name. With
+// StrictNames(false), the default, a row's values may continue onto the
+// following lines. In both modes the name is the first whitespace-separated
+// field, so a name written directly against the first value is not split
+// from it.
+func (s *Scanner) StrictNames(b bool) {
+	s.strictNames = b
+}
+
+// The method SetNamesReader supplies a reader of newline-separated taxon
+// names to use when scanning a NumPy matrix, which has no room for names
+// of its own. Without it, scanned NumPy matrices get placeholder names.
+func (s *Scanner) SetNamesReader(r io.Reader) {
+	s.namesReader = r
+}
+
+// The method Buffer sets the size of the read buffer to the larger of
+// cap(buf) and max (4096 bytes if neither is positive). The storage of buf
+// itself is not used, and the buffer does not limit memory use: a line
+// longer than the buffer is still read in full.
+// It must be called before the first call to Scan.
+func (s *Scanner) Buffer(buf []byte, max int) {
+	if cap(buf) > 0 {
+		s.bufSize = cap(buf)
+	}
+	if max > s.bufSize {
+		s.bufSize = max
+	}
+}
+
+// reader lazily wraps the raw io.Reader in a *bufio.Reader, honoring a
+// prior call to Buffer.
+func (s *Scanner) reader() *bufio.Reader {
+	if s.r == nil {
+		size := s.bufSize
+		if size <= 0 {
+			size = 4096
+		}
+		s.r = bufio.NewReaderSize(s.raw, size)
+	}
+	return s.r
+}
+
+// The method Err returns the first error encountered by Scan, if any. It
+// should be checked after Scan returns false to tell a clean end of input
+// from a malformed one, the same way bufio.Scanner.Err works.
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+// fail records err as the Scanner's error and always returns false, so
+// scan methods can write "return s.fail(err)".
+func (s *Scanner) fail(err error) bool {
+	s.err = err
+	return false
+}
+
+// Scan reads input matrix by matrix. It returns false at the end of input
+// or after the first malformed matrix; call Err to tell the two apart.
+func (s *Scanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+	f := s.format
+	if f == Auto {
+		f = s.detect()
+	}
+	switch f {
+	case PhylipLower:
+		return s.scanPhylipLower()
+	case CSV:
+		return s.scanCSV()
+	case NumPy:
+		return s.scanNumPy()
+	default:
+		return s.scanPhylipSquare()
+	}
+}
+
+// The method Matrices returns an iterator over the matrices in the input,
+// for range-over-func consumers:
+//
+//	for dm := range sc.Matrices() { ... }
+//
+// Iteration stops at the first error; check Err afterwards.
+func (s *Scanner) Matrices() iter.Seq[*DistMat] {
+	return func(yield func(*DistMat) bool) {
+		for s.Scan() {
+			if !yield(s.DistanceMatrix()) {
+				return
+			}
+		}
+	}
+}
+
+// detect peeks at the unread input and guesses its Format: the .npy magic
+// bytes mean NumPy, a comma on the first non-empty line means CSV, and
+// anything else, such as a lone integer, means PhylipSquare.
+func (s *Scanner) detect() Format {
+	b, _ := s.reader().Peek(512)
+	if bytes.HasPrefix(b, []byte(npyMagic)) {
+		return NumPy
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.Contains(line, ",") {
+			return CSV
+		}
+		break
+	}
+	return PhylipSquare
+}
+
+// scanPhylipSquare reads the classic square PHYLIP format, in either of
+// its two common dialects: sequential, where each taxon's row is complete
+// on one line, or interleaved, where it is split across blocks of lines
+// separated by a blank line. The dialect is told apart by counting the
+// fields on the first data line.
+func (s *Scanner) scanPhylipSquare() bool {
+	r := s.reader()
+	const num = "1234567890"
+	l, err := r.ReadString('\n')
+	for err == nil && strings.IndexAny(l, num) < 0 {
+		l, err = r.ReadString('\n')
+	}
+	if err != nil {
+		return false
+	}
+	l = strings.TrimRight(l, "\r\n")
+	n, err := strconv.Atoi(l)
+	if err != nil {
+		return s.fail(fmt.Errorf("can't convert %q", l))
+	}
+	s.mat = NewDistMat(n)
+	l, err = r.ReadString('\n')
+	if err != nil && l == "" {
+		return s.fail(fmt.Errorf("unexpected end of matrix after header"))
+	}
+	fields := strings.Fields(l)
+	if len(fields) == 0 {
+		return s.fail(fmt.Errorf("empty row 0"))
+	}
+	if len(fields)-1 >= n {
+		return s.scanPhylipSquareSequential(n, fields)
+	}
+	return s.scanPhylipSquareInterleaved(n, fields)
+}
+
+// scanPhylipSquareSequential reads a sequential-dialect matrix, whose
+// first data line, already split into fields, is passed in.
+func (s *Scanner) scanPhylipSquareSequential(n int, first []string) bool {
+	r := s.reader()
+	fields := first
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			l, err := r.ReadString('\n')
+			if err != nil && l == "" {
+				return s.fail(fmt.Errorf("unexpected end of matrix at row %d", i))
+			}
+			fields = strings.Fields(l)
+		}
+		if len(fields) < n+1 {
+			return s.fail(fmt.Errorf("row %d: got %d fields, want %d", i, len(fields)-1, n))
+		}
+		s.mat.Names[i] = fields[0]
+		for j := 1; j <= n; j++ {
+			v, err := strconv.ParseFloat(fields[j], 64)
+			if err != nil {
+				return s.fail(fmt.Errorf("can't read %q", fields[j]))
+			}
+			s.mat.Matrix[i][j-1] = v
+		}
+	}
+	return true
+}
+
+// scanPhylipSquareInterleaved reads an interleaved-dialect matrix, whose
+// first block's first line, already split into fields, is passed in. It
+// keeps reading blocks of n lines, separated by a blank line, until the
+// first taxon has collected n values, and then fails unless every other
+// taxon has exactly n values too.
+func (s *Scanner) scanPhylipSquareInterleaved(n int, first []string) bool {
+	r := s.reader()
+	values := make([][]string, n)
+	fields := first
+	for block := 0; values[0] == nil || len(values[0]) < n; block++ {
+		for i := 0; i < n; i++ {
+			if block > 0 || i > 0 {
+				l, err := r.ReadString('\n')
+				for err == nil && strings.TrimSpace(l) == "" {
+					l, err = r.ReadString('\n')
+				}
+				if err != nil && l == "" {
+					return s.fail(fmt.Errorf("unexpected end of matrix in block %d", block))
+				}
+				fields = strings.Fields(l)
+			}
+			if block == 0 {
+				s.mat.Names[i] = fields[0]
+				values[i] = append(values[i], fields[1:]...)
+			} else {
+				values[i] = append(values[i], fields...)
+			}
+		}
+	}
+	for i := 0; i < n; i++ {
+		if len(values[i]) != n {
+			return s.fail(fmt.Errorf("row %d: got %d values, want %d", i, len(values[i]), n))
+		}
+		for j := 0; j < n; j++ {
+			v, err := strconv.ParseFloat(values[i][j], 64)
+			if err != nil {
+				return s.fail(fmt.Errorf("can't read %q", values[i][j]))
+			}
+			s.mat.Matrix[i][j] = v
+		}
+	}
+	return true
+}
+
+// scanPhylipLower reads the lower-triangular PHYLIP format, where row i
+// holds a name followed by i values, and mirrors them into a full matrix.
+func (s *Scanner) scanPhylipLower() bool {
+	r := s.reader()
+	l, err := r.ReadString('\n')
+	for err == nil && strings.TrimSpace(l) == "" {
+		l, err = r.ReadString('\n')
+	}
+	if err != nil {
+		return false
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(l))
+	if err != nil {
+		return s.fail(fmt.Errorf("can't convert %q", l))
+	}
+	s.mat = NewDistMat(n)
+	for i := 0; i < n; i++ {
+		l, err = r.ReadString('\n')
+		if err != nil && l == "" {
+			return s.fail(fmt.Errorf("unexpected end of matrix at row %d", i))
+		}
+		fields := strings.Fields(l)
+		if len(fields) == 0 {
+			return s.fail(fmt.Errorf("empty row %d", i))
+		}
+		s.mat.Names[i] = fields[0]
+		vals := fields[1:]
+		for !s.strictNames && len(vals) < i && err == nil {
+			l, err = r.ReadString('\n')
+			vals = append(vals, strings.Fields(l)...)
+		}
+		if len(vals) != i {
+			return s.fail(fmt.Errorf("row %d: got %d values, want %d", i, len(vals), i))
+		}
+		for j := 0; j < i; j++ {
+			v, err := strconv.ParseFloat(vals[j], 64)
+			if err != nil {
+				return s.fail(fmt.Errorf("can't read %q", vals[j]))
+			}
+			s.mat.Matrix[i][j] = v
+			s.mat.Matrix[j][i] = v
+		}
+	}
+	return true
+}
+
+// scanCSV reads a CSV matrix: a header row of taxon names (with an empty
+// first cell), followed by one row per taxon, each prefixed with its name.
+func (s *Scanner) scanCSV() bool {
+	cr := csv.NewReader(s.reader())
+	records, err := cr.ReadAll()
+	if err != nil {
+		return s.fail(err)
+	}
+	if len(records) == 0 {
+		return false
+	}
+	names := records[0][1:]
+	n := len(names)
+	if len(records) != n+1 {
+		return s.fail(fmt.Errorf("CSV: got %d data rows, want %d", len(records)-1, n))
+	}
+	s.mat = NewDistMat(n)
+	copy(s.mat.Names, names)
+	for i := 0; i < n; i++ {
+		row := records[i+1]
+		for j := 0; j < n; j++ {
+			v, err := strconv.ParseFloat(row[j+1], 64)
+			if err != nil {
+				return s.fail(fmt.Errorf("can't read %q", row[j+1]))
+			}
+			s.mat.Matrix[i][j] = v
+		}
+	}
+	return true
+}
+
+// scanNumPy reads a square matrix from a little-endian float64 .npy array.
+// Taxon names come from s.namesReader if set, or are placeholders.
+func (s *Scanner) scanNumPy() bool {
+	r := s.reader()
+	magic := make([]byte, 8)
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return false
+	}
+	if string(magic[:6]) != npyMagic {
+		return s.fail(fmt.Errorf("not a .npy file"))
+	}
+	major := magic[6]
+	var headerLen int
+	if major >= 2 {
+		var hl uint32
+		if err := binary.Read(r, binary.LittleEndian, &hl); err != nil {
+			return s.fail(err)
+		}
+		headerLen = int(hl)
+	} else {
+		var hl uint16
+		if err := binary.Read(r, binary.LittleEndian, &hl); err != nil {
+			return s.fail(err)
+		}
+		headerLen = int(hl)
+	}
+	header := make([]byte, headerLen)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return s.fail(err)
+	}
+	n, err := npyShape(string(header))
+	if err != nil {
+		return s.fail(fmt.Errorf("can't parse .npy header: %v", err))
+	}
+	s.mat = NewDistMat(n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			var v float64
+			if err := binary.Read(r, binary.LittleEndian, &v); err != nil {
+				return s.fail(err)
+			}
+			s.mat.Matrix[i][j] = v
+		}
+	}
+	if s.namesReader != nil {
+		nsc := bufio.NewScanner(s.namesReader)
+		i := 0
+		for ; i < n && nsc.Scan(); i++ {
+			s.mat.Names[i] = nsc.Text()
+		}
+		if err := nsc.Err(); err != nil {
+			return s.fail(err)
+		}
+		if i != n {
+			return s.fail(fmt.Errorf("names reader: got %d names, want %d", i, n))
+		}
+	} else {
+		for i := 0; i < n; i++ {
+			s.mat.Names[i] = fmt.Sprintf("t%d", i)
+		}
+	}
+	return true
+}
+
+// npyShapeRe extracts the first dimension out of a .npy header's
+// "'shape': (n, n)" entry.
+var npyShapeRe = regexp.MustCompile(`'shape':\s*\((\d+)`)
+
+func npyShape(header string) (int, error) {
+	m := npyShapeRe.FindStringSubmatch(header)
+	if m == nil {
+		return 0, fmt.Errorf("no shape found in header %q", header)
+	}
+	return strconv.Atoi(m[1])
+}
+
+// The method DistanceMatrix returns the last DistMat scanned.
+func (s *Scanner) DistanceMatrix() *DistMat {
+	return s.mat
+}
+
+// The method Write writes a distance matrix in the given Format. NumPy
+// output carries no names; use WriteNumPy to also write a sidecar.
+func (d *DistMat) Write(w io.Writer, f Format) error {
+	switch f {
+	case PhylipLower:
+		return d.writePhylipLower(w)
+	case CSV:
+		return d.writeCSV(w)
+	case NumPy:
+		return d.WriteNumPy(w, nil)
+	default:
+		_, err := io.WriteString(w, d.String())
+		return err
+	}
+}
+
+// writePhylipLower writes the matrix in lower-triangular PHYLIP format.
+func (d *DistMat) writePhylipLower(w io.Writer) error {
+	n := len(d.Names)
+	if _, err := fmt.Fprintf(w, "%d\n", n); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if _, err := fmt.Fprintf(w, "%s", d.Names[i]); err != nil {
+			return err
+		}
+		for j := 0; j < i; j++ {
+			if _, err := fmt.Fprintf(w, "\t%.3g", d.Matrix[i][j]); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprint(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCSV writes the matrix as CSV: a header row of taxon names preceded
+// by an empty cell, then one row per taxon, each prefixed with its name.
+func (d *DistMat) writeCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	n := len(d.Names)
+	header := append([]string{""}, d.Names...)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		row := make([]string, n+1)
+		row[0] = d.Names[i]
+		for j := 0; j < n; j++ {
+			row[j+1] = strconv.FormatFloat(d.Matrix[i][j], 'g', -1, 64)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// The method WriteNumPy writes the matrix as a little-endian float64 .npy
+// array to w. If namesW is non-nil, the taxon names are also written to it,
+// one per line, as the .npy format has no room for them.
+func (d *DistMat) WriteNumPy(w io.Writer, namesW io.Writer) error {
+	n := len(d.Names)
+	header := fmt.Sprintf("{'descr': '<f8', 'fortran_order': False, 'shape': (%d, %d), }", n, n)
+	const prefixLen = 10
+	pad := 64 - (prefixLen+len(header)+1)%64
+	header = header + strings.Repeat(" ", pad) + "\n"
+	if _, err := io.WriteString(w, npyMagic); err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte{1, 0}); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint16(len(header))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, header); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if err := binary.Write(w, binary.LittleEndian, d.Matrix[i][j]); err != nil {
+				return err
+			}
+		}
+	}
+	if namesW != nil {
+		for _, name := range d.Names {
+			if _, err := fmt.Fprintln(namesW, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package dist
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func symmetricMat() *DistMat {
+	d := NewDistMat(3)
+	copy(d.Names, []string{"a", "b", "c"})
+	d.Matrix[0] = []float64{0, 1, 2}
+	d.Matrix[1] = []float64{1, 0, 3}
+	d.Matrix[2] = []float64{2, 3, 0}
+	return d
+}
+
+func TestWriteScanPhylipSquare(t *testing.T) {
+	d := symmetricMat()
+	var buf bytes.Buffer
+	if err := d.Write(&buf, PhylipSquare); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	sc := NewScannerFormat(&buf, PhylipSquare)
+	if !sc.Scan() {
+		t.Fatalf("Scan failed: %v", sc.Err())
+	}
+	got := sc.DistanceMatrix()
+	if !reflect.DeepEqual(got.Names, d.Names) || !reflect.DeepEqual(got.Matrix, d.Matrix) {
+		t.Errorf("get:\n%+v\nwant:\n%+v", got, d)
+	}
+}
+
+func TestWriteScanPhylipLower(t *testing.T) {
+	d := symmetricMat()
+	var buf bytes.Buffer
+	if err := d.Write(&buf, PhylipLower); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	sc := NewScannerFormat(&buf, PhylipLower)
+	if !sc.Scan() {
+		t.Fatalf("Scan failed: %v", sc.Err())
+	}
+	got := sc.DistanceMatrix()
+	if !reflect.DeepEqual(got.Names, d.Names) || !reflect.DeepEqual(got.Matrix, d.Matrix) {
+		t.Errorf("get:\n%+v\nwant:\n%+v", got, d)
+	}
+}
+
+func TestWriteScanCSV(t *testing.T) {
+	d := symmetricMat()
+	var buf bytes.Buffer
+	if err := d.Write(&buf, CSV); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	sc := NewScannerFormat(&buf, CSV)
+	if !sc.Scan() {
+		t.Fatalf("Scan failed: %v", sc.Err())
+	}
+	got := sc.DistanceMatrix()
+	if !reflect.DeepEqual(got.Names, d.Names) || !reflect.DeepEqual(got.Matrix, d.Matrix) {
+		t.Errorf("get:\n%+v\nwant:\n%+v", got, d)
+	}
+}
+
+func TestWriteScanNumPy(t *testing.T) {
+	d := symmetricMat()
+	var buf, names bytes.Buffer
+	if err := d.WriteNumPy(&buf, &names); err != nil {
+		t.Fatalf("WriteNumPy: %v", err)
+	}
+	sc := NewScannerFormat(&buf, NumPy)
+	sc.SetNamesReader(&names)
+	if !sc.Scan() {
+		t.Fatalf("Scan failed: %v", sc.Err())
+	}
+	got := sc.DistanceMatrix()
+	if !reflect.DeepEqual(got.Names, d.Names) || !reflect.DeepEqual(got.Matrix, d.Matrix) {
+		t.Errorf("get:\n%+v\nwant:\n%+v", got, d)
+	}
+}
+
+func TestAutoDetect(t *testing.T) {
+	d := symmetricMat()
+	var square, csv bytes.Buffer
+	d.Write(&square, PhylipSquare)
+	d.Write(&csv, CSV)
+
+	sc := NewScannerFormat(&square, Auto)
+	if !sc.Scan() {
+		t.Fatalf("Auto PHYLIP: Scan failed: %v", sc.Err())
+	}
+	if !reflect.DeepEqual(sc.DistanceMatrix().Names, d.Names) {
+		t.Errorf("Auto PHYLIP: got %v, want %v", sc.DistanceMatrix().Names, d.Names)
+	}
+
+	sc = NewScannerFormat(&csv, Auto)
+	if !sc.Scan() {
+		t.Fatalf("Auto CSV: Scan failed: %v", sc.Err())
+	}
+	if !reflect.DeepEqual(sc.DistanceMatrix().Names, d.Names) {
+		t.Errorf("Auto CSV: got %v, want %v", sc.DistanceMatrix().Names, d.Names)
+	}
+}
+
+func TestScanCSVShortRows(t *testing.T) {
+	r := bytes.NewBufferString(",a,b,c\na,0,1,2\nb,1,0,3\n")
+	sc := NewScannerFormat(r, CSV)
+	if sc.Scan() {
+		t.Fatalf("Scan should fail for a short CSV body, got %v", sc.DistanceMatrix())
+	}
+	if sc.Err() == nil {
+		t.Errorf("Err() is nil after a short CSV body, want a row-count mismatch error")
+	}
+}
+
+func TestScanNumPyShortNames(t *testing.T) {
+	d := symmetricMat()
+	var buf, names bytes.Buffer
+	if err := d.WriteNumPy(&buf, &names); err != nil {
+		t.Fatalf("WriteNumPy: %v", err)
+	}
+	short := bytes.NewBufferString("a\nb\n") // only 2 names for 3 taxa
+	sc := NewScannerFormat(&buf, NumPy)
+	sc.SetNamesReader(short)
+	if sc.Scan() {
+		t.Fatalf("Scan should fail for a short names reader, got %v", sc.DistanceMatrix())
+	}
+	if sc.Err() == nil {
+		t.Errorf("Err() is nil after a short names reader, want a name-count mismatch error")
+	}
+}
@@ -0,0 +1,99 @@
+package dist
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func interleavedMat() *DistMat {
+	d := NewDistMat(4)
+	copy(d.Names, []string{"a", "b", "c", "d"})
+	d.Matrix[0] = []float64{0, 1, 2, 3}
+	d.Matrix[1] = []float64{1, 0, 4, 5}
+	d.Matrix[2] = []float64{2, 4, 0, 6}
+	d.Matrix[3] = []float64{3, 5, 6, 0}
+	return d
+}
+
+// interleavedPhylip is interleavedMat written in the interleaved PHYLIP
+// dialect, in two blocks of two columns each, separated by a blank line.
+const interleavedPhylip = "4\n" +
+	"a 0 1\n" +
+	"b 1 0\n" +
+	"c 2 4\n" +
+	"d 3 5\n" +
+	"\n" +
+	"2 3\n" +
+	"4 5\n" +
+	"0 6\n" +
+	"6 0\n"
+
+func TestScanInterleaved(t *testing.T) {
+	sc := NewScannerFormat(bytes.NewBufferString(interleavedPhylip), PhylipSquare)
+	if !sc.Scan() {
+		t.Fatalf("Scan failed: %v", sc.Err())
+	}
+	want := interleavedMat()
+	got := sc.DistanceMatrix()
+	if !reflect.DeepEqual(got.Names, want.Names) || !reflect.DeepEqual(got.Matrix, want.Matrix) {
+		t.Errorf("get:\n%+v\nwant:\n%+v", got, want)
+	}
+}
+
+// multiMatrixPhylip chains two small sequential-dialect matrices with no
+// separator beyond the second matrix's own taxon count line.
+const multiMatrixPhylip = "2\n" +
+	"a 0 1\n" +
+	"b 1 0\n" +
+	"2\n" +
+	"x 0 5\n" +
+	"y 5 0\n"
+
+func TestScanMultiMatrix(t *testing.T) {
+	sc := NewScannerFormat(bytes.NewBufferString(multiMatrixPhylip), PhylipSquare)
+	var got []*DistMat
+	for sc.Scan() {
+		got = append(got, sc.DistanceMatrix())
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatalf("unexpected error at end of stream: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d matrices, want 2", len(got))
+	}
+	if !reflect.DeepEqual(got[0].Names, []string{"a", "b"}) {
+		t.Errorf("matrix 1 names: get %v, want [a b]", got[0].Names)
+	}
+	if !reflect.DeepEqual(got[1].Names, []string{"x", "y"}) {
+		t.Errorf("matrix 2 names: get %v, want [x y]", got[1].Names)
+	}
+}
+
+func TestScanMatricesIterator(t *testing.T) {
+	sc := NewScannerFormat(bytes.NewBufferString(multiMatrixPhylip), PhylipSquare)
+	var names [][]string
+	for dm := range sc.Matrices() {
+		names = append(names, dm.Names)
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := [][]string{{"a", "b"}, {"x", "y"}}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("get %v, want %v", names, want)
+	}
+}
+
+func TestScanMalformedSetsErr(t *testing.T) {
+	sc := NewScannerFormat(bytes.NewBufferString("2\na 0 x\nb 1 0\n"), PhylipSquare)
+	if sc.Scan() {
+		t.Fatalf("Scan should fail on an unparsable distance, got %v", sc.DistanceMatrix())
+	}
+	if sc.Err() == nil {
+		t.Errorf("Err() is nil after a malformed matrix")
+	}
+	if sc.Scan() {
+		t.Errorf("Scan should keep returning false once Err() is set")
+	}
+}
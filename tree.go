@@ -0,0 +1,152 @@
+package dist
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// clone returns a deep copy of a DistMat so that tree-building algorithms can
+// freely delete and append rows without disturbing the caller's matrix.
+func (d *DistMat) clone() *DistMat {
+	c := new(DistMat)
+	c.Names = append([]string{}, d.Names...)
+	c.Matrix = make([][]float64, len(d.Matrix))
+	for i, row := range d.Matrix {
+		c.Matrix[i] = append([]float64{}, row...)
+	}
+	return c
+}
+
+// The method NeighborJoin builds a tree from the distance matrix using the
+// neighbor-joining algorithm and returns it as a Newick string with branch
+// lengths. The receiver is left unchanged.
+func (d *DistMat) NeighborJoin() string {
+	m := d.clone()
+	switch len(m.Names) {
+	case 0:
+		return ";"
+	case 1:
+		return m.Names[0] + ";"
+	}
+	internal := 0
+	for len(m.Names) > 2 {
+		n := len(m.Names)
+		r := make([]float64, n)
+		for i := 0; i < n; i++ {
+			sum := 0.0
+			for k := 0; k < n; k++ {
+				sum += m.Matrix[i][k]
+			}
+			r[i] = sum
+		}
+		minQ := math.MaxFloat64
+		minI, minJ := 0, 1
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				q := float64(n-2)*m.Matrix[i][j] - r[i] - r[j]
+				if q < minQ {
+					minQ = q
+					minI, minJ = i, j
+				}
+			}
+		}
+		dij := m.Matrix[minI][minJ]
+		dIU := dij/2 + (r[minI]-r[minJ])/(2*float64(n-2))
+		dJU := dij - dIU
+		internal++
+		label := fmt.Sprintf("(%s:%.6f,%s:%.6f)N%d",
+			m.Names[minI], dIU, m.Names[minJ], dJU, internal)
+		newDist := make([]float64, 0, n-2)
+		for k := 0; k < n; k++ {
+			if k == minI || k == minJ {
+				continue
+			}
+			newDist = append(newDist, (m.Matrix[minI][k]+m.Matrix[minJ][k]-dij)/2)
+		}
+		m.DeletePair(minI, minJ)
+		m.Append(label, newDist)
+	}
+	// The final two nodes are joined by a single edge of length d01. Since
+	// Newick has no unrooted-tree notation, that edge is rooted at its
+	// midpoint, giving both sides a branch length of d01/2; the two halves
+	// still sum to the patristic distance d01, and this matches how UPGMA
+	// roots its own final join.
+	d01 := m.Matrix[0][1]
+	return fmt.Sprintf("(%s:%.6f,%s:%.6f);", m.Names[0], d01/2, m.Names[1], d01/2)
+}
+
+// The method UPGMA builds a tree from the distance matrix using UPGMA
+// (unweighted pair group method with arithmetic mean) and returns it as a
+// Newick string with branch lengths. The receiver is left unchanged.
+func (d *DistMat) UPGMA() string {
+	m := d.clone()
+	switch len(m.Names) {
+	case 0:
+		return ";"
+	case 1:
+		return m.Names[0] + ";"
+	}
+	n := len(m.Names)
+	heights := make([]float64, n)
+	sizes := make([]int, n)
+	for i := range sizes {
+		sizes[i] = 1
+	}
+	internal := 0
+	for len(m.Names) > 2 {
+		_, i, j := m.Min()
+		dij := m.Matrix[i][j]
+		h := dij / 2
+		li := h - heights[i]
+		lj := h - heights[j]
+		internal++
+		label := fmt.Sprintf("(%s:%.6f,%s:%.6f)N%d",
+			m.Names[i], li, m.Names[j], lj, internal)
+		nn := len(m.Names)
+		newDist := make([]float64, 0, nn-2)
+		for k := 0; k < nn; k++ {
+			if k == i || k == j {
+				continue
+			}
+			nd := (float64(sizes[i])*m.Matrix[i][k] +
+				float64(sizes[j])*m.Matrix[j][k]) / float64(sizes[i]+sizes[j])
+			newDist = append(newDist, nd)
+		}
+		newSize := sizes[i] + sizes[j]
+		heights = deleteTwo(heights, i, j)
+		sizes = deleteTwo(sizes, i, j)
+		m.DeletePair(i, j)
+		m.Append(label, newDist)
+		heights = append(heights, h)
+		sizes = append(sizes, newSize)
+	}
+	dij := m.Matrix[0][1]
+	h := dij / 2
+	l0 := h - heights[0]
+	l1 := h - heights[1]
+	return fmt.Sprintf("(%s:%.6f,%s:%.6f);", m.Names[0], l0, m.Names[1], l1)
+}
+
+// deleteTwo removes the entries at indexes i and j from s, preserving the
+// order of the remaining entries. It mirrors the semantics of DeletePair for
+// the auxiliary slices the tree builders keep alongside a DistMat.
+func deleteTwo[T any](s []T, i, j int) []T {
+	r := make([]T, 0, len(s)-2)
+	for k, v := range s {
+		if k == i || k == j {
+			continue
+		}
+		r = append(r, v)
+	}
+	return r
+}
+
+// WriteNewick writes a Newick tree string to w, as returned by NeighborJoin
+// or UPGMA, making sure it ends in a single newline.
+func WriteNewick(w io.Writer, tree string) error {
+	tree = strings.TrimRight(tree, "\n") + "\n"
+	_, err := io.WriteString(w, tree)
+	return err
+}
@@ -0,0 +1,79 @@
+package dist
+
+import "testing"
+
+// additiveMat returns the standard 5-taxon additive distance matrix used
+// to teach neighbor-joining (Durbin et al., Biological Sequence Analysis),
+// whose true tree has branch lengths a:2, b:3, c:4, d:2, e:1 around two
+// internal edges of length 3 and 2.
+func additiveMat() *DistMat {
+	names := []string{"a", "b", "c", "d", "e"}
+	rows := [][]float64{
+		{0, 5, 9, 9, 8},
+		{5, 0, 10, 10, 9},
+		{9, 10, 0, 8, 7},
+		{9, 10, 8, 0, 3},
+		{8, 9, 7, 3, 0},
+	}
+	d := NewDistMat(len(names))
+	copy(d.Names, names)
+	for i, row := range rows {
+		copy(d.Matrix[i], row)
+	}
+	return d
+}
+
+func TestNeighborJoin(t *testing.T) {
+	d := additiveMat()
+	want := "((c:4.000000,(a:2.000000,b:3.000000)N1:3.000000)N2:1.000000,(d:2.000000,e:1.000000)N3:1.000000);"
+	get := d.NeighborJoin()
+	if get != want {
+		t.Errorf("get:\n%s\nwant:\n%s", get, want)
+	}
+	// The receiver must be left unchanged.
+	if len(d.Names) != 5 {
+		t.Errorf("NeighborJoin mutated the receiver")
+	}
+}
+
+func TestUPGMA(t *testing.T) {
+	d := additiveMat()
+	want := "((a:2.500000,b:2.500000)N2:2.083333,(c:3.750000,(d:1.500000,e:1.500000)N1:2.250000)N3:0.833333);"
+	get := d.UPGMA()
+	if get != want {
+		t.Errorf("get:\n%s\nwant:\n%s", get, want)
+	}
+	if len(d.Names) != 5 {
+		t.Errorf("UPGMA mutated the receiver")
+	}
+}
+
+func TestNeighborJoinUPGMAEdgeCases(t *testing.T) {
+	d0 := NewDistMat(0)
+	if get := d0.NeighborJoin(); get != ";" {
+		t.Errorf("NeighborJoin n=0: get %q, want %q", get, ";")
+	}
+	if get := d0.UPGMA(); get != ";" {
+		t.Errorf("UPGMA n=0: get %q, want %q", get, ";")
+	}
+
+	d1 := NewDistMat(1)
+	d1.Names[0] = "a"
+	if get := d1.NeighborJoin(); get != "a;" {
+		t.Errorf("NeighborJoin n=1: get %q, want %q", get, "a;")
+	}
+	if get := d1.UPGMA(); get != "a;" {
+		t.Errorf("UPGMA n=1: get %q, want %q", get, "a;")
+	}
+
+	d2 := NewDistMat(2)
+	d2.Names[0], d2.Names[1] = "a", "b"
+	d2.Matrix[0][1], d2.Matrix[1][0] = 5, 5
+	want2 := "(a:2.500000,b:2.500000);"
+	if get := d2.NeighborJoin(); get != want2 {
+		t.Errorf("NeighborJoin n=2: get %q, want %q", get, want2)
+	}
+	if get := d2.UPGMA(); get != want2 {
+		t.Errorf("UPGMA n=2: get %q, want %q", get, want2)
+	}
+}